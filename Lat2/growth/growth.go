@@ -0,0 +1,379 @@
+// Package growth menilai pertumbuhan anak dengan WHO Child Growth Standards 2006:
+// z-score BB/U, PB/U atau TB/U, BB/PB atau BB/TB dan IMT/U dihitung dengan metode LMS,
+// lalu diklasifikasikan menurut Permenkes No. 2 Tahun 2020 tentang Standar Antropometri Anak.
+package growth
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tabel LMS WHO 2006 dibundel ke dalam binary dari folder tables/.
+// Nama file dan formatnya dijelaskan di tables/README.md.
+//
+//go:embed tables
+var embeddedTables embed.FS
+
+// Jenis kelamin anak, sama dengan nilai field sex pada registri anak
+const (
+	SexMale   = "L" // laki-laki
+	SexFemale = "P" // perempuan
+)
+
+// Indikator pertumbuhan WHO
+const (
+	IndicatorWeightForAge       = "wfa"  // berat badan menurut umur (BB/U)
+	IndicatorLengthHeightForAge = "lhfa" // panjang/tinggi badan menurut umur (PB/U atau TB/U)
+	IndicatorWeightForLength    = "wfl"  // berat badan menurut panjang badan (BB/PB), umur 0-23 bulan
+	IndicatorWeightForHeight    = "wfh"  // berat badan menurut tinggi badan (BB/TB), umur 24-60 bulan
+	IndicatorBMIForAge          = "bfa"  // IMT menurut umur (IMT/U)
+)
+
+// Satu bulan rata-rata dalam hari, sesuai yang dipakai WHO
+const daysPerMonth = 30.4375
+
+// Umur maksimum yang dicakup WHO Child Growth Standards (60 bulan)
+const maxAgeDays = 1856
+
+// lmsRow adalah satu baris tabel LMS. Key berupa umur dalam hari
+// atau panjang/tinggi badan dalam cm, tergantung indikatornya.
+type lmsRow struct {
+	Key, L, M, S float64
+}
+
+// lmsTable adalah tabel LMS yang sudah diurutkan berdasarkan Key.
+type lmsTable []lmsRow
+
+// lookup mencari nilai L, M dan S untuk key tertentu, dengan interpolasi
+// linear di antara dua baris jika key tidak tepat berada di tabel.
+func (t lmsTable) lookup(key float64) (lmsRow, bool) {
+	if len(t) == 0 || key < t[0].Key || key > t[len(t)-1].Key {
+		return lmsRow{}, false
+	}
+	i := sort.Search(len(t), func(i int) bool { return t[i].Key >= key })
+	if t[i].Key == key || i == 0 {
+		return t[i], true
+	}
+	lo, hi := t[i-1], t[i]
+	f := (key - lo.Key) / (hi.Key - lo.Key)
+	return lmsRow{
+		Key: key,
+		L:   lo.L + f*(hi.L-lo.L),
+		M:   lo.M + f*(hi.M-lo.M),
+		S:   lo.S + f*(hi.S-lo.S),
+	}, true
+}
+
+// valueAt menghitung nilai pengukuran pada z-score tertentu (kebalikan dari zScore).
+func (row lmsRow) valueAt(z float64) float64 {
+	if row.L == 0 {
+		return row.M * math.Exp(row.S*z)
+	}
+	return row.M * math.Pow(1+row.L*row.S*z, 1/row.L)
+}
+
+// zScore menghitung z-score dengan metode LMS.
+// Jika restricted bernilai true, z-score di luar ±3 dihitung ulang memakai jarak
+// antara SD2 dan SD3, sesuai aturan WHO untuk indikator berbasis berat badan.
+func (row lmsRow) zScore(x float64, restricted bool) float64 {
+	var z float64
+	if row.L == 0 {
+		z = math.Log(x/row.M) / row.S
+	} else {
+		z = (math.Pow(x/row.M, row.L) - 1) / (row.L * row.S)
+	}
+	if !restricted {
+		return z
+	}
+
+	switch {
+	case z > 3:
+		sd3, sd2 := row.valueAt(3), row.valueAt(2)
+		z = 3 + (x-sd3)/(sd3-sd2)
+	case z < -3:
+		sd3, sd2 := row.valueAt(-3), row.valueAt(-2)
+		z = -3 + (x-sd3)/(sd2-sd3)
+	}
+	return z
+}
+
+// Reference berisi semua tabel LMS yang berhasil dimuat, per indikator dan jenis kelamin.
+// Reference nil boleh dipakai: semua z-score bernilai null.
+type Reference struct {
+	tables  map[string]map[string]lmsTable
+	missing []string
+}
+
+// tableFiles memetakan indikator dan jenis kelamin ke nama file tabel WHO.
+var tableFiles = map[string]map[string]string{
+	IndicatorWeightForAge:       {SexMale: "wfa_boys.txt", SexFemale: "wfa_girls.txt"},
+	IndicatorLengthHeightForAge: {SexMale: "lhfa_boys.txt", SexFemale: "lhfa_girls.txt"},
+	IndicatorWeightForLength:    {SexMale: "wfl_boys.txt", SexFemale: "wfl_girls.txt"},
+	IndicatorWeightForHeight:    {SexMale: "wfh_boys.txt", SexFemale: "wfh_girls.txt"},
+	IndicatorBMIForAge:          {SexMale: "bfa_boys.txt", SexFemale: "bfa_girls.txt"},
+}
+
+// LoadEmbedded memuat tabel LMS yang dibundel di folder tables/ (lihat tables/README.md).
+// Berbeda dengan Load, semua tabel wajib ada: binary tanpa tabel lengkap akan menghasilkan
+// z-score dan status BGM null untuk setiap pengukuran, jadi dianggap error.
+func LoadEmbedded() (*Reference, error) {
+	sub, err := fs.Sub(embeddedTables, "tables")
+	if err != nil {
+		return nil, err
+	}
+	ref, err := Load(sub)
+	if err != nil {
+		return nil, err
+	}
+	if len(ref.missing) > 0 {
+		return nil, fmt.Errorf("tabel bawaan tidak lengkap, file tidak ditemukan di tables/: %s", strings.Join(ref.missing, ", "))
+	}
+	return ref, nil
+}
+
+// Load memuat tabel LMS dari fsys. File yang tidak ada dilewati; indikator tanpa tabel
+// akan menghasilkan z-score null, dan nama filenya dilaporkan oleh Missing.
+func Load(fsys fs.FS) (*Reference, error) {
+	ref := &Reference{tables: map[string]map[string]lmsTable{}}
+
+	for indicator, files := range tableFiles {
+		ref.tables[indicator] = map[string]lmsTable{}
+		for sex, name := range files {
+			raw, err := fs.ReadFile(fsys, name)
+			if errors.Is(err, fs.ErrNotExist) {
+				ref.missing = append(ref.missing, name)
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("gagal membaca tabel %s: %w", name, err)
+			}
+			table, err := parseLMSTable(raw)
+			if err != nil {
+				return nil, fmt.Errorf("tabel %s tidak valid: %w", name, err)
+			}
+			ref.tables[indicator][sex] = table
+		}
+	}
+
+	sort.Strings(ref.missing)
+	return ref, nil
+}
+
+// Missing mengembalikan nama file tabel yang tidak ditemukan saat Load, terurut.
+func (ref *Reference) Missing() []string {
+	if ref == nil {
+		return nil
+	}
+	return ref.missing
+}
+
+// Loaded melaporkan apakah tabel indikator untuk kedua jenis kelamin sudah dimuat.
+func (ref *Reference) Loaded(indicator string) bool {
+	if ref == nil {
+		return false
+	}
+	return len(ref.tables[indicator][SexMale]) > 0 && len(ref.tables[indicator][SexFemale]) > 0
+}
+
+// parseLMSTable membaca tabel teks WHO (dipisah tab, koma atau spasi) dengan baris header.
+// Kolom pertama adalah key: "Day" atau "Month" untuk umur, "Length" atau "Height" untuk cm.
+// Kolom L, M dan S dicari berdasarkan nama header; kolom lain (SD, persentil) diabaikan.
+func parseLMSTable(raw []byte) (lmsTable, error) {
+	splitLine := func(line string) []string {
+		return strings.FieldsFunc(line, func(r rune) bool {
+			return r == '\t' || r == ',' || r == ';' || r == ' '
+		})
+	}
+
+	lines := strings.Split(strings.ReplaceAll(string(raw), "\r\n", "\n"), "\n")
+	if len(lines) < 2 {
+		return nil, fmt.Errorf("tabel kosong")
+	}
+
+	header := splitLine(lines[0])
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	keyScale := 1.0
+	switch strings.ToLower(header[0]) {
+	case "day", "length", "height":
+	case "month":
+		keyScale = daysPerMonth
+	default:
+		return nil, fmt.Errorf("kolom pertama harus Day, Month, Length atau Height, bukan '%s'", header[0])
+	}
+	for _, name := range []string{"l", "m", "s"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("kolom %s tidak ditemukan", strings.ToUpper(name))
+		}
+	}
+
+	var table lmsTable
+	for n, line := range lines[1:] {
+		fields := splitLine(line)
+		if len(fields) == 0 {
+			continue
+		}
+		var values [4]float64
+		for i, col := range []int{0, columns["l"], columns["m"], columns["s"]} {
+			if col >= len(fields) {
+				return nil, fmt.Errorf("baris %d: jumlah kolom kurang", n+2)
+			}
+			v, err := strconv.ParseFloat(fields[col], 64)
+			if err != nil {
+				return nil, fmt.Errorf("baris %d: %v", n+2, err)
+			}
+			values[i] = v
+		}
+		table = append(table, lmsRow{Key: values[0] * keyScale, L: values[1], M: values[2], S: values[3]})
+	}
+
+	sort.Slice(table, func(i, j int) bool { return table[i].Key < table[j].Key })
+	return table, nil
+}
+
+// zScore menghitung z-score untuk satu indikator. ok bernilai false jika
+// tabelnya tidak dimuat atau key berada di luar cakupan tabel.
+func (ref *Reference) zScore(indicator, sex string, key, value float64) (float64, bool) {
+	if ref == nil {
+		return 0, false
+	}
+	row, ok := ref.tables[indicator][sex].lookup(key)
+	if !ok {
+		return 0, false
+	}
+	// Hanya PB/U dan TB/U yang tidak memakai aturan ±3 SD WHO
+	restricted := indicator != IndicatorLengthHeightForAge
+	z := row.zScore(value, restricted)
+	return math.Round(z*100) / 100, true
+}
+
+// ZScores berisi z-score per indikator; null jika tidak bisa dihitung.
+type ZScores struct {
+	WeightForAge          *float64 `json:"weight_for_age"`
+	LengthHeightForAge    *float64 `json:"length_height_for_age"`
+	WeightForLengthHeight *float64 `json:"weight_for_length_height"`
+	BMIForAge             *float64 `json:"bmi_for_age"`
+}
+
+// Status berisi klasifikasi status gizi per indikator.
+type Status struct {
+	WeightForAge          string `json:"weight_for_age,omitempty"`
+	LengthHeightForAge    string `json:"length_height_for_age,omitempty"`
+	WeightForLengthHeight string `json:"weight_for_length_height,omitempty"`
+	BMIForAge             string `json:"bmi_for_age,omitempty"`
+}
+
+// Result adalah hasil penilaian pertumbuhan untuk satu pengukuran.
+type Result struct {
+	AgeDays   int     `json:"age_days"`
+	AgeMonths float64 `json:"age_months"`
+	BMI       float64 `json:"bmi"`
+	ZScores   ZScores `json:"z_scores"`
+	Status    Status  `json:"status"`
+}
+
+// Assess menghitung z-score dan klasifikasi dari berat (kg), panjang/tinggi (cm),
+// tanggal lahir dan jenis kelamin anak pada waktu pengukuran.
+// Anak di bawah 24 bulan dianggap diukur berbaring (panjang badan),
+// anak 24 bulan ke atas diukur berdiri (tinggi badan).
+func (ref *Reference) Assess(weight, height float64, birth time.Time, sex string, at time.Time) *Result {
+	ageDays := int(at.Sub(birth).Hours() / 24)
+	if ageDays < 0 || ageDays > maxAgeDays || height <= 0 {
+		return nil
+	}
+
+	result := &Result{
+		AgeDays:   ageDays,
+		AgeMonths: math.Round(float64(ageDays)/daysPerMonth*10) / 10,
+		BMI:       math.Round(weight/math.Pow(height/100, 2)*100) / 100,
+	}
+	age := float64(ageDays)
+
+	if z, ok := ref.zScore(IndicatorWeightForAge, sex, age, weight); ok {
+		result.ZScores.WeightForAge = &z
+		result.Status.WeightForAge = classifyWeightForAge(z)
+	}
+	if z, ok := ref.zScore(IndicatorLengthHeightForAge, sex, age, height); ok {
+		result.ZScores.LengthHeightForAge = &z
+		result.Status.LengthHeightForAge = classifyLengthHeightForAge(z)
+	}
+
+	wfhIndicator := IndicatorWeightForHeight
+	if age < 24*daysPerMonth {
+		wfhIndicator = IndicatorWeightForLength
+	}
+	// Panjang/tinggi dibulatkan ke 0,1 cm seperti pada tabel WHO
+	if z, ok := ref.zScore(wfhIndicator, sex, math.Round(height*10)/10, weight); ok {
+		result.ZScores.WeightForLengthHeight = &z
+		result.Status.WeightForLengthHeight = classifyWeightForLengthHeight(z)
+	}
+	if z, ok := ref.zScore(IndicatorBMIForAge, sex, age, result.BMI); ok {
+		result.ZScores.BMIForAge = &z
+		result.Status.BMIForAge = classifyWeightForLengthHeight(z)
+	}
+
+	return result
+}
+
+// Klasifikasi mengikuti WHO dan Permenkes No. 2 Tahun 2020 tentang Standar Antropometri Anak.
+// Nilai di luar batas biologis WHO ditandai "implausible" karena kemungkinan salah ukur.
+
+func classifyWeightForAge(z float64) string {
+	switch {
+	case z < -6 || z > 5:
+		return "implausible"
+	case z < -3:
+		return "severely_underweight"
+	case z < -2:
+		return "underweight"
+	case z <= 1:
+		return "normal"
+	default:
+		return "risk_of_overweight"
+	}
+}
+
+func classifyLengthHeightForAge(z float64) string {
+	switch {
+	case z < -6 || z > 6:
+		return "implausible"
+	case z < -3:
+		return "severely_stunted"
+	case z < -2:
+		return "stunted"
+	case z <= 3:
+		return "normal"
+	default:
+		return "tall"
+	}
+}
+
+// classifyWeightForLengthHeight dipakai untuk BB/PB, BB/TB dan IMT/U yang batasnya sama.
+func classifyWeightForLengthHeight(z float64) string {
+	switch {
+	case z < -5 || z > 5:
+		return "implausible"
+	case z < -3:
+		return "severely_wasted"
+	case z < -2:
+		return "wasted"
+	case z <= 1:
+		return "normal"
+	case z <= 2:
+		return "possible_risk_of_overweight"
+	case z <= 3:
+		return "overweight"
+	default:
+		return "obese"
+	}
+}
@@ -0,0 +1,217 @@
+package growth
+
+import (
+	"math"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// Baris lahir (umur 0 hari) dari tabel WHO 2006 untuk pengujian perhitungan LMS.
+// Kolom SD pada tabel WHO dibulatkan ke 0,1 sehingga dibandingkan dengan toleransi 0,05.
+var testTables = fstest.MapFS{
+	"wfa_boys.txt":   {Data: []byte("Day\tL\tM\tS\n0\t0.3487\t3.3464\t0.14602\n")},
+	"wfa_girls.txt":  {Data: []byte("Day\tL\tM\tS\n0\t0.3809\t3.2322\t0.14171\n")},
+	"lhfa_boys.txt":  {Data: []byte("Day\tL\tM\tS\n0\t1\t49.8842\t0.03795\n")},
+	"lhfa_girls.txt": {Data: []byte("Day\tL\tM\tS\n0\t1\t49.1477\t0.0379\n")},
+}
+
+func loadTestReference(t *testing.T) *Reference {
+	t.Helper()
+	ref, err := Load(testTables)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return ref
+}
+
+func TestValueAtMatchesWHOStandardDeviations(t *testing.T) {
+	ref := loadTestReference(t)
+
+	// Kolom SD3neg sampai SD3 pada tabel WHO umur 0 bulan
+	cases := []struct {
+		indicator, sex string
+		want           [7]float64
+	}{
+		{IndicatorWeightForAge, SexMale, [7]float64{2.1, 2.5, 2.9, 3.3, 3.9, 4.4, 5.0}},
+		{IndicatorWeightForAge, SexFemale, [7]float64{2.0, 2.4, 2.8, 3.2, 3.7, 4.2, 4.8}},
+		{IndicatorLengthHeightForAge, SexMale, [7]float64{44.2, 46.1, 48.0, 49.9, 51.8, 53.7, 55.6}},
+		{IndicatorLengthHeightForAge, SexFemale, [7]float64{43.6, 45.4, 47.3, 49.1, 51.0, 52.9, 54.7}},
+	}
+	for _, c := range cases {
+		row, ok := ref.tables[c.indicator][c.sex].lookup(0)
+		if !ok {
+			t.Fatalf("%s/%s: baris umur 0 tidak ditemukan", c.indicator, c.sex)
+		}
+		for i, want := range c.want {
+			z := float64(i - 3)
+			if got := row.valueAt(z); math.Abs(got-want) > 0.05 {
+				t.Errorf("%s/%s SD%+.0f = %.4f, want %.1f", c.indicator, c.sex, z, got, want)
+			}
+		}
+	}
+}
+
+func TestZScore(t *testing.T) {
+	ref := loadTestReference(t)
+
+	cases := []struct {
+		name           string
+		indicator, sex string
+		value, want    float64
+	}{
+		{"median BB/U", IndicatorWeightForAge, SexMale, 3.3464, 0},
+		{"SD2neg BB/U", IndicatorWeightForAge, SexMale, 2.4593, -2},
+		{"SD2 BB/U perempuan", IndicatorWeightForAge, SexFemale, 4.2304, 2},
+		{"SD2neg PB/U", IndicatorLengthHeightForAge, SexMale, 46.1, -2},
+		// Di luar ±3 SD berat badan memakai jarak SD2-SD3, bukan rumus LMS langsung (4,43 dan -4,79)
+		{"BB/U di atas SD3", IndicatorWeightForAge, SexMale, 6.0, 4.59},
+		{"BB/U di bawah SD3neg", IndicatorWeightForAge, SexMale, 1.5, -4.53},
+		// PB/U tidak memakai aturan ±3 SD
+		{"PB/U di bawah SD3neg", IndicatorLengthHeightForAge, SexMale, 40, -5.22},
+	}
+	for _, c := range cases {
+		got, ok := ref.zScore(c.indicator, c.sex, 0, c.value)
+		if !ok {
+			t.Errorf("%s: z-score tidak dihitung", c.name)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("%s: z = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestLookupInterpolates(t *testing.T) {
+	table, err := parseLMSTable([]byte("Month,L,M,S\n0,1,50,0.04\n1,1,54,0.03\n"))
+	if err != nil {
+		t.Fatalf("parseLMSTable: %v", err)
+	}
+	if table[1].Key != daysPerMonth {
+		t.Fatalf("key bulan 1 = %v, want %v hari", table[1].Key, daysPerMonth)
+	}
+
+	row, ok := table.lookup(daysPerMonth / 2)
+	if !ok {
+		t.Fatal("lookup di tengah tabel gagal")
+	}
+	if row.M != 52 || math.Abs(row.S-0.035) > 1e-12 {
+		t.Errorf("interpolasi = %+v, want M 52 dan S 0.035", row)
+	}
+	if _, ok := table.lookup(daysPerMonth + 1); ok {
+		t.Error("lookup di luar tabel seharusnya gagal")
+	}
+}
+
+func TestParseLMSTableErrors(t *testing.T) {
+	cases := map[string]string{
+		"kosong":          "",
+		"kolom pertama":   "Week\tL\tM\tS\n0\t1\t50\t0.04\n",
+		"kolom S hilang":  "Day\tL\tM\n0\t1\t50\n",
+		"kolom kurang":    "Day\tL\tM\tS\n0\t1\t50\n",
+		"angka tidak sah": "Day\tL\tM\tS\n0\t1\tx\t0.04\n",
+	}
+	for name, raw := range cases {
+		if _, err := parseLMSTable([]byte(raw)); err == nil {
+			t.Errorf("%s: seharusnya error", name)
+		}
+	}
+}
+
+func TestLoadEmbedded(t *testing.T) {
+	ref, err := LoadEmbedded()
+	if err != nil {
+		t.Fatalf("LoadEmbedded: %v", err)
+	}
+	if missing := ref.Missing(); len(missing) > 0 {
+		t.Fatalf("tabel bawaan tidak lengkap: %v", missing)
+	}
+}
+
+func TestLoadReportsMissingTables(t *testing.T) {
+	ref := loadTestReference(t)
+
+	want := []string{"bfa_boys.txt", "bfa_girls.txt", "wfh_boys.txt", "wfh_girls.txt", "wfl_boys.txt", "wfl_girls.txt"}
+	got := ref.Missing()
+	if len(got) != len(want) {
+		t.Fatalf("Missing() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Missing() = %v, want %v", got, want)
+		}
+	}
+
+	if !ref.Loaded(IndicatorWeightForAge) || ref.Loaded(IndicatorWeightForLength) {
+		t.Errorf("Loaded: BB/U seharusnya dimuat, BB/PB tidak")
+	}
+	var nilRef *Reference
+	if nilRef.Loaded(IndicatorWeightForAge) {
+		t.Error("Reference nil seharusnya tidak memuat tabel apa pun")
+	}
+}
+
+func TestAssess(t *testing.T) {
+	ref := loadTestReference(t)
+	birth := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	result := ref.Assess(2.4, 45.0, birth, SexMale, birth.Add(6*time.Hour))
+	if result == nil {
+		t.Fatal("Assess mengembalikan nil")
+	}
+	if result.AgeDays != 0 {
+		t.Errorf("AgeDays = %d, want 0", result.AgeDays)
+	}
+	if result.ZScores.WeightForAge == nil || result.Status.WeightForAge != "underweight" {
+		t.Errorf("BB/U = %v %q, want underweight", result.ZScores.WeightForAge, result.Status.WeightForAge)
+	}
+	if result.ZScores.LengthHeightForAge == nil || result.Status.LengthHeightForAge != "stunted" {
+		t.Errorf("PB/U = %v %q, want stunted", result.ZScores.LengthHeightForAge, result.Status.LengthHeightForAge)
+	}
+	// Tabel BB/PB dan IMT/U tidak dimuat
+	if result.ZScores.WeightForLengthHeight != nil || result.ZScores.BMIForAge != nil {
+		t.Errorf("z-score tanpa tabel seharusnya null: %+v", result.ZScores)
+	}
+
+	if ref.Assess(3, 50, birth, SexMale, birth.AddDate(0, 0, -1)) != nil {
+		t.Error("pengukuran sebelum lahir seharusnya tidak dinilai")
+	}
+	if ref.Assess(3, 50, birth, SexMale, birth.AddDate(6, 0, 0)) != nil {
+		t.Error("anak di atas 60 bulan seharusnya tidak dinilai")
+	}
+
+	var nilRef *Reference
+	if result := nilRef.Assess(3.3, 50, birth, SexMale, birth); result == nil || result.ZScores.WeightForAge != nil {
+		t.Errorf("Reference nil seharusnya menghasilkan z-score null, dapat %+v", result)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		name     string
+		classify func(float64) string
+		z        float64
+		want     string
+	}{
+		{"BB/U", classifyWeightForAge, -3.5, "severely_underweight"},
+		{"BB/U", classifyWeightForAge, -2, "normal"},
+		{"BB/U", classifyWeightForAge, -2.01, "underweight"},
+		{"BB/U", classifyWeightForAge, 1.5, "risk_of_overweight"},
+		{"BB/U", classifyWeightForAge, -6.5, "implausible"},
+		{"PB/U", classifyLengthHeightForAge, -3.01, "severely_stunted"},
+		{"PB/U", classifyLengthHeightForAge, -2.5, "stunted"},
+		{"PB/U", classifyLengthHeightForAge, 3, "normal"},
+		{"PB/U", classifyLengthHeightForAge, 3.5, "tall"},
+		{"BB/TB", classifyWeightForLengthHeight, -3.2, "severely_wasted"},
+		{"BB/TB", classifyWeightForLengthHeight, -2.2, "wasted"},
+		{"BB/TB", classifyWeightForLengthHeight, 1.5, "possible_risk_of_overweight"},
+		{"BB/TB", classifyWeightForLengthHeight, 2.5, "overweight"},
+		{"BB/TB", classifyWeightForLengthHeight, 3.5, "obese"},
+		{"BB/TB", classifyWeightForLengthHeight, 5.5, "implausible"},
+	}
+	for _, c := range cases {
+		if got := c.classify(c.z); got != c.want {
+			t.Errorf("%s z=%v: %q, want %q", c.name, c.z, got, c.want)
+		}
+	}
+}
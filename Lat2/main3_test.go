@@ -0,0 +1,615 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// testServer adalah server httptest dengan penyimpanan in-memory, satu alat di posyandu
+// Melati, dan token akses untuk admin serta kader Melati.
+type testServer struct {
+	*httptest.Server
+	deviceKey   string
+	adminToken  string
+	kaderToken  string
+	measurement *memoryMeasurementStore
+}
+
+// newTestServer menyiapkan variabel global yang dipakai handler dan mendaftarkan endpoint
+// pengukuran dengan middleware yang sama seperti di run. Variabel global dikembalikan
+// seperti semula setelah test selesai.
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	oldConfig, oldStore, oldChildStore, oldDeviceStore, oldUserStore := config, store, childStore, deviceStore, userStore
+	t.Cleanup(func() {
+		config, store, childStore, deviceStore, userStore = oldConfig, oldStore, oldChildStore, oldDeviceStore, oldUserStore
+	})
+
+	config = defaultConfig()
+	config.Store = "memory"
+	config.Auth.JWTSecret = "rahasia-test-yang-cukup-panjang-32b"
+
+	measurements := newMemoryMeasurementStore()
+	store = measurements
+	childStore = newMemoryChildStore()
+	deviceStore = newMemoryDeviceStore()
+	userStore = newMemoryUserStore()
+
+	ctx := context.Background()
+	key, keyHash, prefix, err := newDeviceKey()
+	if err != nil {
+		t.Fatalf("newDeviceKey: %v", err)
+	}
+	device := Device{ID: primitive.NewObjectID(), Name: "Timbangan 1", Posyandu: "Melati", KeyHash: keyHash, KeyPrefix: prefix, CreatedAt: time.Now()}
+	if err := deviceStore.InsertDevice(ctx, device); err != nil {
+		t.Fatalf("InsertDevice: %v", err)
+	}
+
+	adminToken, err := issueAccessToken(User{ID: primitive.NewObjectID(), Username: "admin", Role: RoleAdmin}, time.Now())
+	if err != nil {
+		t.Fatalf("issueAccessToken: %v", err)
+	}
+	kaderToken, err := issueAccessToken(User{ID: primitive.NewObjectID(), Username: "kader.melati", Role: RoleKader, Posyandu: "Melati"}, time.Now())
+	if err != nil {
+		t.Fatalf("issueAccessToken: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/data", enableCORS(methods(http.MethodGet, http.MethodPost), requireDatabase(limitBody(jsonBodyLimit, handlerApiDataRoot))))
+	mux.HandleFunc("/api/showall", enableCORS(methods(http.MethodGet), requireDatabase(requireUser(handlerApiShowAll))))
+	mux.HandleFunc("/api/data/", enableCORS(dataByRFIDMethods, requireDatabase(limitBody(dataByRFIDBodyLimit, handlerApiDataByRFID))))
+	mux.HandleFunc("/api/children/", enableCORS(childByIDMethods, requireDatabase(limitBody(jsonBodyLimit, requireUser(handlerApiChildByID)))))
+
+	srv := httptest.NewServer(withRequestID(mux))
+	t.Cleanup(srv.Close)
+
+	return &testServer{
+		Server:      srv,
+		deviceKey:   key,
+		adminToken:  adminToken,
+		kaderToken:  kaderToken,
+		measurement: measurements,
+	}
+}
+
+// seed menyimpan pengukuran langsung ke store, satu menit terpisah sesuai urutan.
+func (s *testServer) seed(t *testing.T, items ...Alat) []Alat {
+	t.Helper()
+	start := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	for i := range items {
+		items[i].ID = primitive.NewObjectID()
+		items[i].IngestionTimestamp = start.Add(time.Duration(i) * time.Minute)
+		if err := s.measurement.Insert(context.Background(), items[i]); err != nil {
+			t.Fatalf("Insert: %v", err)
+		}
+	}
+	return items
+}
+
+// do mengirim request dan mengembalikan status beserta body respons.
+func (s *testServer) do(t *testing.T, method, path string, body string, header ...string) (int, []byte) {
+	t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(method, s.URL+path, reader)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	res, err := s.Client().Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer res.Body.Close()
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("membaca body %s %s: %v", method, path, err)
+	}
+	return res.StatusCode, raw
+}
+
+// get mengirim GET dengan token akses tertentu.
+func (s *testServer) get(t *testing.T, path, token string, header ...string) (int, []byte) {
+	t.Helper()
+	return s.do(t, http.MethodGet, path, "", append([]string{"Authorization", "Bearer " + token}, header...)...)
+}
+
+func decodeJSON[T any](t *testing.T, raw []byte) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal(raw, &v); err != nil {
+		t.Fatalf("body bukan JSON yang valid: %v\n%s", err, raw)
+	}
+	return v
+}
+
+func TestCreateMeasurement(t *testing.T) {
+	srv := newTestServer(t)
+
+	status, raw := srv.do(t, http.MethodPost, "/api/data", `{"rfid":"A1","weight":9.5,"height":75}`)
+	if status != http.StatusUnauthorized {
+		t.Fatalf("tanpa API key: status %d, want 401: %s", status, raw)
+	}
+
+	status, raw = srv.do(t, http.MethodPost, "/api/data", `{"rfid":"A1","weight":0,"height":75}`, deviceKeyHeader, srv.deviceKey)
+	if status != http.StatusBadRequest {
+		t.Fatalf("berat 0: status %d, want 400: %s", status, raw)
+	}
+	apiErr := decodeJSON[APIError](t, raw)
+	if apiErr.Code != ErrCodeValidation || len(apiErr.Details) != 1 || apiErr.Details[0].Field != "weight" {
+		t.Errorf("error validasi = %+v, want field weight", apiErr)
+	}
+
+	status, raw = srv.do(t, http.MethodPost, "/api/data", `{"rfid":"A1","weight":9.5,"height":75,"extra":1}`, deviceKeyHeader, srv.deviceKey)
+	if status != http.StatusBadRequest {
+		t.Fatalf("field tak dikenal: status %d, want 400: %s", status, raw)
+	}
+
+	// Wilayah ditentukan server dari registri anak, bukan dari alat
+	status, raw = srv.do(t, http.MethodPost, "/api/data", `{"rfid":" A1 ","weight":9.5,"height":75,"posyandu":"Mawar"}`, deviceKeyHeader, srv.deviceKey)
+	if status != http.StatusCreated {
+		t.Fatalf("status %d, want 201: %s", status, raw)
+	}
+	created := decodeJSON[Alat](t, raw)
+	if created.ID.IsZero() || created.RFID != "A1" || created.DeviceID == nil || created.IngestionTimestamp.IsZero() {
+		t.Errorf("respons = %+v, want _id, rfid A1, device_id dan ingestion_timestamp terisi", created)
+	}
+
+	stored, err := store.Get(context.Background(), AccessScope{}, created.ID)
+	if err != nil {
+		t.Fatalf("pengukuran tidak tersimpan: %v", err)
+	}
+	if stored.Weight != 9.5 || stored.Height != 75 || stored.Posyandu != "" {
+		t.Errorf("tersimpan %+v, want berat 9.5, tinggi 75 dan tanpa posyandu", stored)
+	}
+}
+
+func TestLatestMeasurement(t *testing.T) {
+	srv := newTestServer(t)
+
+	if status, raw := srv.get(t, "/api/data", srv.adminToken); status != http.StatusNotFound {
+		t.Fatalf("store kosong: status %d, want 404: %s", status, raw)
+	}
+
+	srv.seed(t,
+		Alat{RFID: "A1", Weight: 9, Height: 75, Posyandu: "Melati"},
+		Alat{RFID: "B2", Weight: 11, Height: 80, Posyandu: "Melati"},
+		Alat{RFID: "C3", Weight: 12, Height: 85, Posyandu: "Mawar"},
+	)
+
+	if status, raw := srv.do(t, http.MethodGet, "/api/data", ""); status != http.StatusUnauthorized {
+		t.Fatalf("tanpa login: status %d, want 401: %s", status, raw)
+	}
+
+	status, raw := srv.get(t, "/api/data", srv.adminToken)
+	if status != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", status, raw)
+	}
+	if latest := decodeJSON[Alat](t, raw); latest.RFID != "C3" {
+		t.Errorf("admin: terbaru %q, want C3", latest.RFID)
+	}
+
+	// Kader hanya melihat pengukuran di posyandu-nya
+	status, raw = srv.get(t, "/api/data", srv.kaderToken)
+	if status != http.StatusOK {
+		t.Fatalf("kader: status %d, want 200: %s", status, raw)
+	}
+	if latest := decodeJSON[Alat](t, raw); latest.RFID != "B2" {
+		t.Errorf("kader: terbaru %q, want B2", latest.RFID)
+	}
+}
+
+func TestLatestMeasurementByRFID(t *testing.T) {
+	srv := newTestServer(t)
+	items := srv.seed(t,
+		Alat{RFID: "A1", Weight: 9, Height: 75, Posyandu: "Melati"},
+		Alat{RFID: "A1", Weight: 9.4, Height: 76, Posyandu: "Melati"},
+		Alat{RFID: "B2", Weight: 11, Height: 80, Posyandu: "Melati"},
+		Alat{RFID: "C3", Weight: 12, Height: 85, Posyandu: "Mawar"},
+	)
+
+	status, raw := srv.get(t, "/api/data/A1", srv.adminToken)
+	if status != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", status, raw)
+	}
+	if latest := decodeJSON[Alat](t, raw); latest.ID != items[1].ID {
+		t.Errorf("terbaru untuk A1 = %s, want %s", latest.ID.Hex(), items[1].ID.Hex())
+	}
+
+	status, raw = srv.get(t, "/api/data/ZZ", srv.adminToken)
+	if status != http.StatusNotFound {
+		t.Fatalf("RFID tidak dikenal: status %d, want 404: %s", status, raw)
+	}
+	if apiErr := decodeJSON[APIError](t, raw); apiErr.Code != ErrCodeNotFound || apiErr.RequestID == "" {
+		t.Errorf("error = %+v, want code not_found dengan request_id", apiErr)
+	}
+
+	// Pengukuran di posyandu lain tidak terlihat oleh kader
+	if status, raw := srv.get(t, "/api/data/C3", srv.kaderToken); status != http.StatusNotFound {
+		t.Errorf("kader ke RFID posyandu lain: status %d, want 404: %s", status, raw)
+	}
+
+	if status, raw := srv.get(t, "/api/data/A1", "token-palsu"); status != http.StatusUnauthorized {
+		t.Errorf("token tidak valid: status %d, want 401: %s", status, raw)
+	}
+}
+
+func TestShowAll(t *testing.T) {
+	srv := newTestServer(t)
+	srv.seed(t,
+		Alat{RFID: "A1", Weight: 9, Height: 75, Posyandu: "Melati"},
+		Alat{RFID: "B2", Weight: 12, Height: 80, Posyandu: "Melati"},
+		Alat{RFID: "C3", Weight: 8, Height: 70, Posyandu: "Mawar"},
+		Alat{RFID: "D4", Weight: 10, Height: 78, Posyandu: "Melati"},
+		Alat{RFID: "E5", Weight: 11, Height: 79, Posyandu: "Mawar"},
+	)
+
+	type page struct {
+		Data []Alat `json:"data"`
+		Next *string
+	}
+
+	var weights []float64
+	next := "/api/showall?limit=2&sort=-weight"
+	for pages := 0; next != ""; pages++ {
+		if pages > 3 {
+			t.Fatal("halaman tidak pernah berakhir")
+		}
+		status, raw := srv.get(t, next, srv.adminToken)
+		if status != http.StatusOK {
+			t.Fatalf("GET %s: status %d: %s", next, status, raw)
+		}
+		p := decodeJSON[page](t, raw)
+		for _, item := range p.Data {
+			weights = append(weights, item.Weight)
+		}
+		next = ""
+		if p.Next != nil {
+			next = *p.Next
+		}
+	}
+	want := []float64{12, 11, 10, 9, 8}
+	if len(weights) != len(want) {
+		t.Fatalf("berat = %v, want %v", weights, want)
+	}
+	for i := range want {
+		if weights[i] != want[i] {
+			t.Fatalf("berat = %v, want %v", weights, want)
+		}
+	}
+
+	status, raw := srv.get(t, "/api/showall", srv.kaderToken)
+	if status != http.StatusOK {
+		t.Fatalf("kader: status %d: %s", status, raw)
+	}
+	for _, item := range decodeJSON[page](t, raw).Data {
+		if item.Posyandu != "Melati" {
+			t.Errorf("kader Melati melihat pengukuran posyandu %q", item.Posyandu)
+		}
+	}
+
+	if status, raw := srv.get(t, "/api/showall?sort=rfid", srv.adminToken); status != http.StatusBadRequest {
+		t.Errorf("sort tidak dikenal: status %d, want 400: %s", status, raw)
+	}
+	if status, raw := srv.get(t, "/api/showall?limit=0", srv.adminToken); status != http.StatusBadRequest {
+		t.Errorf("limit 0: status %d, want 400: %s", status, raw)
+	}
+}
+
+func TestShowAllNDJSON(t *testing.T) {
+	srv := newTestServer(t)
+	srv.seed(t,
+		Alat{RFID: "A1", Weight: 9, Height: 75},
+		Alat{RFID: "B2", Weight: 12, Height: 80},
+		Alat{RFID: "C3", Weight: 8, Height: 70},
+	)
+
+	status, raw := srv.get(t, "/api/showall?limit=2", srv.adminToken, "Accept", "application/x-ndjson")
+	if status != http.StatusOK {
+		t.Fatalf("status %d: %s", status, raw)
+	}
+	lines := bytes.Split(bytes.TrimSpace(raw), []byte("\n"))
+	if len(lines) != 3 {
+		t.Fatalf("jumlah baris %d, want 2 dokumen dan 1 baris penutup:\n%s", len(lines), raw)
+	}
+	closing := decodeJSON[map[string]*string](t, lines[2])
+	if closing["next"] == nil || !strings.HasPrefix(*closing["next"], "/api/showall?") {
+		t.Fatalf("baris penutup = %s, want link halaman berikutnya", lines[2])
+	}
+
+	status, raw = srv.get(t, *closing["next"], srv.adminToken, "Accept", "application/x-ndjson")
+	if status != http.StatusOK {
+		t.Fatalf("halaman kedua: status %d: %s", status, raw)
+	}
+	lines = bytes.Split(bytes.TrimSpace(raw), []byte("\n"))
+	if len(lines) != 2 || string(lines[1]) != `{"next":null}` {
+		t.Fatalf("halaman terakhir:\n%s\nwant 1 dokumen dan {\"next\":null}", raw)
+	}
+}
+
+func TestAssignRFIDConflict(t *testing.T) {
+	srv := newTestServer(t)
+	ctx := context.Background()
+	date := func(month time.Month, day int) time.Time { return time.Date(2024, month, day, 0, 0, 0, 0, time.UTC) }
+	ended := date(3, 1)
+
+	others := []Child{
+		{Name: "Ayu", RFIDs: []RFIDAssignment{{RFID: "T1", ValidFrom: date(1, 1), ValidTo: &ended}}},
+		{Name: "Bima", RFIDs: []RFIDAssignment{{RFID: "T2", ValidFrom: date(1, 1)}}},
+		{Name: "Citra", RFIDs: []RFIDAssignment{{RFID: "T3", ValidFrom: date(6, 1)}}},
+	}
+	target := Child{ID: primitive.NewObjectID(), Name: "Dewi", Posyandu: "Melati"}
+	for _, child := range append(others, target) {
+		if child.ID.IsZero() {
+			child.ID = primitive.NewObjectID()
+			child.Posyandu = "Melati"
+		}
+		if err := childStore.InsertChild(ctx, child); err != nil {
+			t.Fatalf("InsertChild: %v", err)
+		}
+	}
+
+	cases := []struct {
+		name, rfid string
+		validFrom  time.Time
+		want       int
+	}{
+		{"dipakai anak lain pada valid_from", "T1", date(2, 1), http.StatusConflict},
+		{"dipakai anak lain mulai setelah valid_from", "T3", date(5, 1), http.StatusConflict},
+		{"dipakai anak lain tanpa akhir sejak setelah valid_from", "T2", date(1, 1).AddDate(0, -1, 0), http.StatusConflict},
+		{"pemakaian anak lain berakhir tepat pada valid_from", "T1", ended, http.StatusOK},
+	}
+	for _, c := range cases {
+		body := `{"rfid":"` + c.rfid + `","valid_from":"` + c.validFrom.Format(time.RFC3339) + `"}`
+		status, raw := srv.do(t, http.MethodPost, "/api/children/"+target.ID.Hex()+"/rfid", body,
+			"Authorization", "Bearer "+srv.kaderToken, "Content-Type", "application/json")
+		if status != c.want {
+			t.Errorf("%s: status %d, want %d: %s", c.name, status, c.want, raw)
+		}
+	}
+}
+
+func TestMakeThumbnailTransparentPNG(t *testing.T) {
+	var raw bytes.Buffer
+	if err := png.Encode(&raw, image.NewNRGBA(image.Rect(0, 0, 40, 20))); err != nil {
+		t.Fatalf("png.Encode: %v", err)
+	}
+
+	thumbnail, err := makeThumbnail(&raw, 10)
+	if err != nil {
+		t.Fatalf("makeThumbnail: %v", err)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(thumbnail))
+	if err != nil {
+		t.Fatalf("thumbnail bukan JPEG: %v", err)
+	}
+	if size := img.Bounds().Size(); size != image.Pt(10, 5) {
+		t.Errorf("ukuran thumbnail %v, want 10x5", size)
+	}
+	if r, g, b, _ := img.At(5, 2).RGBA(); r>>8 < 250 || g>>8 < 250 || b>>8 < 250 {
+		t.Errorf("piksel transparan menjadi (%d, %d, %d), want putih", r>>8, g>>8, b>>8)
+	}
+}
+
+func TestKMSMinimumGain(t *testing.T) {
+	cases := map[int]int{0: 800, 1: 800, 2: 900, 6: 400, 7: 400, 8: 300, 10: 300, 11: 200, 59: 200}
+	for months, want := range cases {
+		if got := kmsMinimumGain(months); got != want {
+			t.Errorf("kmsMinimumGain(%d) = %d, want %d", months, got, want)
+		}
+	}
+}
+
+func TestCompletedMonths(t *testing.T) {
+	cases := []struct {
+		birth, at time.Time
+		want      int
+	}{
+		{localDate(2024, 1, 15, 0), localDate(2024, 2, 14, 23), 0},
+		{localDate(2024, 1, 15, 0), localDate(2024, 2, 15, 0), 1},
+		{localDate(2023, 8, 15, 0), localDate(2024, 3, 20, 9), 7},
+		{localDate(2023, 12, 31, 0), localDate(2024, 1, 31, 0), 1},
+		{localDate(2024, 1, 31, 0), localDate(2024, 2, 29, 0), 0},
+	}
+	for _, c := range cases {
+		if got := completedMonths(c.birth, c.at); got != c.want {
+			t.Errorf("completedMonths(%s, %s) = %d, want %d", c.birth.Format(time.DateOnly), c.at.Format(time.DateOnly), got, c.want)
+		}
+	}
+}
+
+func TestAssessKMS(t *testing.T) {
+	// Umur 7 bulan penuh pada 15 Maret 2024, 8 bulan penuh mulai 15 April 2024
+	child := Child{BirthDate: Date{localDate(2023, 8, 15, 0)}, Sex: SexMale}
+	weighing := func(weight float64, at time.Time, kms *KMSAssessment) Alat {
+		return Alat{RFID: "A1", Weight: weight, IngestionTimestamp: at, KMS: kms}
+	}
+	tidakNaik := func(consecutive int) *KMSAssessment {
+		return &KMSAssessment{Status: KMSTidakNaik, ConsecutiveT: consecutive}
+	}
+
+	cases := []struct {
+		name        string
+		history     []Alat
+		current     Alat
+		wantStatus  string
+		wantGain    *int
+		wantMinGain int
+		wantT       int
+	}{
+		{
+			name:       "penimbangan pertama",
+			current:    weighing(8, localDate(2024, 3, 20, 9), nil),
+			wantStatus: KMSBaru,
+		},
+		{
+			name:       "bulan lalu tidak ditimbang",
+			history:    []Alat{weighing(7.5, localDate(2024, 1, 20, 9), nil)},
+			current:    weighing(8, localDate(2024, 3, 20, 9), nil),
+			wantStatus: KMSOff,
+		},
+		{
+			name:       "penimbangan bulan ini bukan pembanding",
+			history:    []Alat{weighing(7.5, localDate(2024, 3, 1, 0), nil)},
+			current:    weighing(8, localDate(2024, 3, 20, 9), nil),
+			wantStatus: KMSOff,
+		},
+		{
+			name:       "akhir Januari bukan bulan lalu",
+			history:    []Alat{weighing(7.5, localDate(2024, 1, 31, 23), nil)},
+			current:    weighing(8, localDate(2024, 3, 1, 8), nil),
+			wantStatus: KMSOff,
+		},
+		{
+			name:        "naik tepat KBM 7 bulan",
+			history:     []Alat{weighing(7.6, localDate(2024, 2, 20, 9), nil)},
+			current:     weighing(8, localDate(2024, 3, 20, 9), nil),
+			wantStatus:  KMSNaik,
+			wantGain:    intPtr(400),
+			wantMinGain: 400,
+		},
+		{
+			name:        "naik kurang dari KBM 7 bulan",
+			history:     []Alat{weighing(7.65, localDate(2024, 2, 20, 9), nil)},
+			current:     weighing(8, localDate(2024, 3, 20, 9), nil),
+			wantStatus:  KMSTidakNaik,
+			wantGain:    intPtr(350),
+			wantMinGain: 400,
+			wantT:       1,
+		},
+		{
+			name:        "sehari sebelum umur 8 bulan masih KBM 7 bulan",
+			history:     []Alat{weighing(7.65, localDate(2024, 3, 20, 9), nil)},
+			current:     weighing(8, localDate(2024, 4, 14, 23), nil),
+			wantStatus:  KMSTidakNaik,
+			wantGain:    intPtr(350),
+			wantMinGain: 400,
+			wantT:       1,
+		},
+		{
+			name:        "umur 8 bulan memakai KBM 300 gram",
+			history:     []Alat{weighing(7.65, localDate(2024, 3, 20, 9), nil)},
+			current:     weighing(8, localDate(2024, 4, 15, 0), nil),
+			wantStatus:  KMSNaik,
+			wantGain:    intPtr(350),
+			wantMinGain: 300,
+		},
+		{
+			name: "pembanding adalah penimbangan terakhir bulan lalu",
+			history: []Alat{
+				weighing(7, localDate(2024, 2, 1, 0), nil),
+				weighing(7.9, localDate(2024, 2, 29, 23), nil),
+				weighing(7.95, localDate(2024, 3, 5, 9), nil),
+			},
+			current:     weighing(8, localDate(2024, 3, 20, 9), nil),
+			wantStatus:  KMSTidakNaik,
+			wantGain:    intPtr(100),
+			wantMinGain: 400,
+			wantT:       1,
+		},
+		{
+			name:        "berat turun setelah N",
+			history:     []Alat{weighing(8.2, localDate(2024, 2, 20, 9), &KMSAssessment{Status: KMSNaik})},
+			current:     weighing(8, localDate(2024, 3, 20, 9), nil),
+			wantStatus:  KMSTidakNaik,
+			wantGain:    intPtr(-200),
+			wantMinGain: 400,
+			wantT:       1,
+		},
+		{
+			name:        "2T",
+			history:     []Alat{weighing(8, localDate(2024, 2, 20, 9), tidakNaik(1))},
+			current:     weighing(8, localDate(2024, 3, 20, 9), nil),
+			wantStatus:  KMSTidakNaik,
+			wantGain:    intPtr(0),
+			wantMinGain: 400,
+			wantT:       2,
+		},
+		{
+			name:        "3T",
+			history:     []Alat{weighing(8, localDate(2024, 2, 20, 9), tidakNaik(2))},
+			current:     weighing(8.1, localDate(2024, 3, 20, 9), nil),
+			wantStatus:  KMSTidakNaik,
+			wantGain:    intPtr(100),
+			wantMinGain: 400,
+			wantT:       3,
+		},
+		{
+			name:        "naik memutus rantai T",
+			history:     []Alat{weighing(7.5, localDate(2024, 2, 20, 9), tidakNaik(2))},
+			current:     weighing(8, localDate(2024, 3, 20, 9), nil),
+			wantStatus:  KMSNaik,
+			wantGain:    intPtr(500),
+			wantMinGain: 400,
+		},
+	}
+	for _, c := range cases {
+		got := assessKMS(child, c.history, c.current, nil)
+		if got.Status != c.wantStatus || got.MinGainGrams != c.wantMinGain || got.ConsecutiveT != c.wantT || got.BGM {
+			t.Errorf("%s: %+v, want status %s, KBM %d, T berturut-turut %d", c.name, *got, c.wantStatus, c.wantMinGain, c.wantT)
+		}
+		if (got.GainGrams == nil) != (c.wantGain == nil) || (got.GainGrams != nil && *got.GainGrams != *c.wantGain) {
+			t.Errorf("%s: gain_grams %v, want %v", c.name, got.GainGrams, c.wantGain)
+		}
+	}
+}
+
+func TestAssessKMSBGM(t *testing.T) {
+	child := Child{BirthDate: Date{localDate(2023, 8, 15, 0)}, Sex: SexMale}
+	current := Alat{RFID: "A1", Weight: 5, IngestionTimestamp: localDate(2024, 3, 20, 9)}
+
+	cases := []struct {
+		z    *float64
+		want bool
+	}{
+		{nil, false},
+		{floatPtr(-3), false},
+		{floatPtr(-3.1), true},
+	}
+	for _, c := range cases {
+		if got := assessKMS(child, nil, current, c.z); got.BGM != c.want {
+			t.Errorf("z-score %v: BGM = %v, want %v", c.z, got.BGM, c.want)
+		}
+	}
+}
+
+func TestKMSAlertsRequiresGrowthTables(t *testing.T) {
+	oldGrowthRef := growthRef
+	t.Cleanup(func() { growthRef = oldGrowthRef })
+	growthRef = nil
+
+	rec := httptest.NewRecorder()
+	handlerApiKMSAlerts(rec, httptest.NewRequest(http.MethodGet, "/api/kms/alerts", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status %d, want 503: %s", rec.Code, rec.Body)
+	}
+	if body := decodeJSON[APIError](t, rec.Body.Bytes()); body.Code != ErrCodeUnavailable {
+		t.Fatalf("code %q, want %q", body.Code, ErrCodeUnavailable)
+	}
+}
+
+// localDate membuat waktu di zona waktu server, sama seperti kalender bulan pada assessKMS.
+func localDate(year int, month time.Month, day, hour int) time.Time {
+	return time.Date(year, month, day, hour, 0, 0, 0, time.Local)
+}
+
+func intPtr(v int) *int { return &v }
+
+func floatPtr(v float64) *float64 { return &v }